@@ -0,0 +1,207 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Command describes a named subcommand: its own options, an optional
+// handler and its own nested subcommands. A `Command` tree is rooted
+// at the application's top-level command and is driven with
+// `ParseCommands`, the way tools like `git` or `restic` dispatch
+// `git commit` or `restic check` to a handler while still accepting
+// global options defined on the root command.
+type Command struct {
+	// Name is how the command is typed on the command line.
+	// The root command passed to ParseCommands doesn't need a Name.
+	Name string
+	// Description is used by CommandUsage.
+	Description string
+	// Descs are the options accepted by this command, in addition to
+	// the options inherited from its parent commands.
+	Descs []*Desc
+	// Handler is called with the fully merged options and the
+	// command's free arguments once parsing of this command and all
+	// of its parents has finished. It may be nil for commands that
+	// only group subcommands together.
+	Handler func(opts Options, args []string) error
+	// Commands are this command's nested subcommands.
+	Commands []*Command
+}
+
+// ParseCommands parses args against root's options, then, if a free
+// argument remains and root has nested `Commands`, looks that argument
+// up among them and recursively parses the rest of args against the
+// matching subcommand; a free argument naming none of them is an
+// error. This repeats until a command without subcommands, or with no
+// free argument left, is reached. Only that last command's `Handler`,
+// if set, is called, with the options accumulated from itself and all
+// of its parents; the handlers of the commands it was reached through
+// are not called.
+//
+// Unlike `Parse`, which interleaves options and arguments freely,
+// ParseCommands stops consuming options at the first free argument so
+// that argument can be looked up as a command name.
+func ParseCommands(args []string, root *Command) (Options, []string, error) {
+	return parseCommand(args, root, nil, nil)
+}
+
+// parseCommand parses args against cmd's own descriptors, merges the
+// result with the options already collected from cmd's parents, and
+// dispatches to the subcommand named by the first free argument if
+// cmd has subcommands, failing if none of them matches.
+func parseCommand(args []string, cmd *Command, inheritedDescs []*Desc, inheritedOpts Options) (Options, []string, error) {
+	descs, err := mergeDescs(inheritedDescs, cmd.Descs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, rest, err := parsePrefix(args, descs)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(append(Options{}, inheritedOpts...), opts...)
+
+	opts, rest, err = applyConstraints(opts, descs, rest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(rest) > 0 && len(cmd.Commands) > 0 {
+		sub := findCommand(cmd.Commands, rest[0])
+		if sub == nil {
+			return nil, nil, fmt.Errorf("unrecognized command '%s'", rest[0])
+		}
+
+		return parseCommand(rest[1:], sub, descs, opts)
+	}
+
+	if cmd.Handler != nil {
+		if err := cmd.Handler(opts, rest); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return opts, rest, nil
+}
+
+// parsePrefix parses args against descs the same way Parse does, but
+// stops as soon as the first free (non-option) argument is reached,
+// returning it and everything after it unparsed instead of continuing
+// to scan the rest of args for options. Unlike Parse, a lone "-" is
+// taken as a free argument rather than as an invalid option.
+func parsePrefix(args []string, descs []*Desc) (Options, []string, error) {
+	var opts []*Option
+
+	for i := 0; i < len(args); {
+		arg := args[i]
+
+		if arg == "--" {
+			return join(opts), args[i+1:], nil
+		} else if strings.HasPrefix(arg, "-") && arg != "-" {
+			o, n, err := parseDashed(descs, args[i:])
+			if err != nil {
+				return nil, nil, err
+			}
+			opts = append(opts, o...)
+			i += n
+		} else {
+			return join(opts), args[i:], nil
+		}
+	}
+
+	return join(opts), nil, nil
+}
+
+// findCommand searches cmds for a command named name.
+func findCommand(cmds []*Command, name string) *Command {
+	for _, c := range cmds {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// mergeDescs returns a new slice with parent's descriptors followed
+// by own's, or an error if own redeclares a short or long name already
+// taken by parent: since findDesc always resolves to the first
+// matching descriptor, a silently shadowed option would never be
+// reachable from the command line.
+func mergeDescs(parent, own []*Desc) ([]*Desc, error) {
+	for _, o := range own {
+		for _, p := range parent {
+			if (o.Short != "" && o.Short == p.Short) ||
+				(o.Long != "" && o.Long == p.Long) {
+				return nil, fmt.Errorf("option '%s' redefines an option "+
+					"already declared by a parent command", descName(o))
+			}
+		}
+	}
+
+	descs := make([]*Desc, 0, len(parent)+len(own))
+	descs = append(descs, parent...)
+	descs = append(descs, own...)
+
+	return descs, nil
+}
+
+// CommandUsage returns usage information for cmd: its options, merged
+// with the options inherited from its parent commands, followed by
+// the list of its nested subcommands, if any. It returns an error if
+// cmd redeclares a short or long name already taken by a parent
+// command (see mergeDescs).
+//
+// Return string example:
+//   -h, --help  display help information and exit
+//
+// Commands:
+//   check  check repository consistency
+//   mount  mount repository as a filesystem
+func CommandUsage(cmd *Command, inherited []*Desc) (string, error) {
+	var b bytes.Buffer
+
+	descs, err := mergeDescs(inherited, cmd.Descs)
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(Usage(descs))
+
+	if len(cmd.Commands) > 0 {
+		b.WriteString("\nCommands:\n")
+
+		var max int
+		for _, c := range cmd.Commands {
+			if len(c.Name) > max {
+				max = len(c.Name)
+			}
+		}
+		for _, c := range cmd.Commands {
+			b.WriteString("  " + c.Name)
+			b.WriteString(strings.Repeat(" ", max-len(c.Name)+2))
+			b.WriteString(c.Description)
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String(), nil
+}
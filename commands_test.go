@@ -0,0 +1,138 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandsDispatch(t *testing.T) {
+	var called string
+	var calledArgs []string
+
+	check := &Command{
+		Name:        "check",
+		Description: "check repository consistency",
+		Descs: []*Desc{
+			{"f", "force", ArgNone, "", "force check", false, nil, nil},
+		},
+		Handler: func(opts Options, args []string) error {
+			called = "check"
+			calledArgs = args
+			if !opts.Bool("verbose") || !opts.Bool("force") {
+				t.Fatal("expected inherited and own options to be set")
+			}
+			return nil
+		},
+	}
+	root := &Command{
+		Descs: []*Desc{
+			{"v", "verbose", ArgNone, "", "be verbose", false, nil, nil},
+		},
+		Commands: []*Command{check},
+	}
+
+	_, rest, err := ParseCommands([]string{"-v", "check", "-f", "a", "b"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if called != "check" {
+		t.Fatal("check command was not dispatched")
+	}
+	if !reflect.DeepEqual(calledArgs, []string{"a", "b"}) {
+		t.Fatalf("unexpected args: %v", calledArgs)
+	}
+	if !reflect.DeepEqual(rest, []string{"a", "b"}) {
+		t.Fatalf("unexpected rest: %v", rest)
+	}
+}
+
+func TestParseCommandsUnrecognized(t *testing.T) {
+	root := &Command{
+		Commands: []*Command{
+			{Name: "check"},
+		},
+	}
+
+	_, _, err := ParseCommands([]string{"mount"}, root)
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized command")
+	}
+}
+
+func TestParseCommandsConstraints(t *testing.T) {
+	root := &Command{
+		Descs: []*Desc{
+			{"n", "name", ArgString, "name", "name", true, nil, nil},
+			{"u", "user", ArgString, "user", "user", false, "bob", nil},
+		},
+	}
+
+	_, _, err := ParseCommands(nil, root)
+	if err == nil {
+		t.Fatal("expected an error for a missing required option")
+	}
+
+	opts, _, err := ParseCommands([]string{"-n", "x"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := opts.String("user"); u != "bob" {
+		t.Fatalf("expected default value to be materialized, got '%s'", u)
+	}
+}
+
+func TestParseCommandsDuplicateOption(t *testing.T) {
+	run := &Command{
+		Name: "run",
+		Descs: []*Desc{
+			{"v", "value", ArgString, "value", "value", false, nil, nil},
+		},
+	}
+	root := &Command{
+		Descs: []*Desc{
+			{"v", "verbose", ArgNone, "", "be verbose", false, nil, nil},
+		},
+		Commands: []*Command{run},
+	}
+
+	_, _, err := ParseCommands([]string{"run", "-v", "hello"}, root)
+	if err == nil {
+		t.Fatal("expected an error for an option redefined by a subcommand")
+	}
+}
+
+func TestParseCommandsNoSubcommand(t *testing.T) {
+	root := &Command{
+		Descs: []*Desc{
+			{"a", "add", ArgNone, "", "add new item", false, nil, nil},
+		},
+	}
+
+	opts, args, err := ParseCommands([]string{"-a", "x", "y"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Bool("add") {
+		t.Fatal("expected 'add' option to be set")
+	}
+	if !reflect.DeepEqual(args, []string{"x", "y"}) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadConfig reads an INI config file from path and produces Options
+// in the same shape Parse returns, out of `key = value` lines whose key
+// is a Desc.Long (or Desc.Short) name in descs; any other key is an
+// error. `[section]` headers are
+// accepted, as plain INI syntax requires, but are otherwise ignored:
+// keys are looked up by name alone, regardless of which section they
+// appear under. Blank lines and lines starting with '#' or ';' are
+// ignored.
+//
+// Use Merge to let command line options, parsed against the same
+// descs, take priority over the ones loaded here.
+func LoadConfig(path string, descs []*Desc) (Options, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var opts Options
+	s := bufio.NewScanner(f)
+	n := 0
+
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: invalid config line", path, n)
+		}
+		key := strings.TrimSpace(parts[0])
+		val := strings.TrimSpace(parts[1])
+
+		d := findDesc(descs, key)
+		if d == nil {
+			return nil, fmt.Errorf("%s:%d: unrecognized option '%s'", path, n, key)
+		}
+
+		if d.Arg == ArgNone {
+			opts = append(opts, &Option{Desc: d})
+			continue
+		}
+
+		v, err := parseArg(d, val)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, n, err)
+		}
+		opts = append(opts, &Option{Desc: d, Args: []interface{}{v}})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return join(opts), nil
+}
+
+// Merge returns cli with every option present in file, but missing
+// from cli, appended to it, so that command line options take
+// priority over options loaded from a config file.
+func Merge(file, cli Options) Options {
+	merged := append(Options{}, cli...)
+
+	for _, fo := range file {
+		if hasOption(merged, fo.Desc) {
+			continue
+		}
+		merged = append(merged, fo)
+	}
+
+	return merged
+}
+
+// Rule constrains the value of a single option by its long name.
+// Rule's expression is one of:
+//	oneof=a b c  value must be one of the space separated choices
+//	ne=value     value must not equal value
+//	isfalse      the option must not be passed
+//	min=N        numeric value must be >= N
+//	max=N        numeric value must be <= N
+type Rule struct {
+	// Path is the constrained option's long name.
+	Path string
+	// Rule is the constraint expression.
+	Rule string
+}
+
+// Policy is an ordered list of Rule, enforced by Validate.
+type Policy []Rule
+
+// LoadPolicy reads a policy file from path: a YAML mapping, one rule
+// per `path: rule` line, e.g. `level: oneof=low medium high`. This is
+// the flat-mapping subset of YAML, which is all a Policy needs, parsed
+// by hand rather than pulling in a full YAML library; any such line is
+// also a valid single-document YAML mapping entry, so the file loads
+// unchanged in a real YAML parser if a caller ever needs one. Blank
+// lines and lines starting with '#' are ignored.
+func LoadPolicy(path string) (Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var policy Policy
+	s := bufio.NewScanner(f)
+	n := 0
+
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: invalid policy line", path, n)
+		}
+
+		policy = append(policy, Rule{
+			Path: strings.TrimSpace(parts[0]),
+			Rule: strings.TrimSpace(parts[1]),
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// Validate enforces every rule of policy against opts, aggregating
+// every violation into a single error listing them all.
+func Validate(opts Options, policy Policy) error {
+	var violations []string
+
+	for _, r := range policy {
+		if err := checkRule(opts, r); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(violations, "; "))
+}
+
+// checkRule enforces a single Rule against opts.
+func checkRule(opts Options, r Rule) error {
+	switch {
+	case r.Rule == "isfalse":
+		if opts.Bool(r.Path) {
+			return fmt.Errorf("option '%s' must not be set", r.Path)
+		}
+	case strings.HasPrefix(r.Rule, "oneof="):
+		choices := strings.Fields(strings.TrimPrefix(r.Rule, "oneof="))
+		v, ok := rawValue(opts, r.Path)
+		if !ok {
+			return nil
+		}
+		for _, c := range choices {
+			if c == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("option '%s' must be one of: %s",
+			r.Path, strings.Join(choices, ", "))
+	case strings.HasPrefix(r.Rule, "ne="):
+		n := strings.TrimPrefix(r.Rule, "ne=")
+		if v, ok := rawValue(opts, r.Path); ok && v == n {
+			return fmt.Errorf("option '%s' must not equal '%s'", r.Path, n)
+		}
+	case strings.HasPrefix(r.Rule, "min="):
+		return checkBound(opts, r, "min=", func(v, n float64) bool { return v >= n })
+	case strings.HasPrefix(r.Rule, "max="):
+		return checkBound(opts, r, "max=", func(v, n float64) bool { return v <= n })
+	default:
+		return fmt.Errorf("unknown policy rule '%s' for option '%s'", r.Rule, r.Path)
+	}
+
+	return nil
+}
+
+// checkBound enforces a numeric "min=" or "max=" Rule by comparing
+// opts' option value against the rule's bound with cmp.
+func checkBound(opts Options, r Rule, prefix string, cmp func(v, n float64) bool) error {
+	bound, err := strconv.ParseFloat(strings.TrimPrefix(r.Rule, prefix), 64)
+	if err != nil {
+		return fmt.Errorf("invalid policy rule '%s' for option '%s'", r.Rule, r.Path)
+	}
+
+	v, ok := rawFloat(opts, r.Path)
+	if !ok {
+		return nil
+	}
+	if !cmp(v, bound) {
+		return fmt.Errorf("option '%s' violates rule '%s'", r.Path, r.Rule)
+	}
+
+	return nil
+}
+
+// rawValue returns an option's value formatted as a string,
+// regardless of its underlying int, float64 or string type.
+func rawValue(opts Options, name string) (string, bool) {
+	v := opts.arg(name)
+	if v == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%v", v), true
+}
+
+// rawFloat returns an int or float64 option's value as a float64.
+func rawFloat(opts Options, name string) (float64, bool) {
+	v := opts.arg(name)
+
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, content string) string {
+	t.Helper()
+	f, err := os.CreateTemp("", "opt-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(f.Name()) })
+
+	return f.Name()
+}
+
+func TestLoadConfigAndMerge(t *testing.T) {
+	descs := []*Desc{
+		{"p", "path", ArgString, "path", "path", false, nil, nil},
+		{"n", "num", ArgInt, "num", "num", false, nil, nil},
+	}
+
+	path := writeTempFile(t, "# comment\npath = /tmp\nnum = 42\n")
+	file, err := LoadConfig(path, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := file.String("path"); s != "/tmp" {
+		t.Fatalf("unexpected path: %s", s)
+	}
+
+	cli, _, err := Parse([]string{"-p", "/home"}, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := Merge(file, cli)
+	if s, _ := merged.String("path"); s != "/home" {
+		t.Fatalf("expected cli value to win, got '%s'", s)
+	}
+	if n, _ := merged.Int("num"); n != 42 {
+		t.Fatalf("expected file value to fill in, got %d", n)
+	}
+}
+
+func TestLoadConfigSections(t *testing.T) {
+	descs := []*Desc{
+		{"p", "path", ArgString, "path", "path", false, nil, nil},
+	}
+
+	path := writeTempFile(t, "[server]\npath = /tmp\n")
+	file, err := LoadConfig(path, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := file.String("path"); s != "/tmp" {
+		t.Fatalf("unexpected path: %s", s)
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	descs := []*Desc{
+		{"l", "level", ArgString, "level", "level", false, nil, nil},
+		{"t", "timeout", ArgInt, "timeout", "timeout", false, nil, nil},
+	}
+
+	opts, _, err := Parse([]string{"-l", "extreme", "-t", "0"}, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := Policy{
+		{Path: "level", Rule: "oneof=low medium high"},
+		{Path: "timeout", Rule: "min=1"},
+	}
+
+	if err := Validate(opts, policy); err == nil {
+		t.Fatal("expected aggregated policy violations")
+	}
+
+	opts, _, err = Parse([]string{"-l", "high", "-t", "5"}, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(opts, policy); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := writeTempFile(t, "# comment\nlevel: oneof=low medium high\ntimeout: min=1\n")
+
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(policy))
+	}
+	if policy[0].Path != "level" || policy[0].Rule != "oneof=low medium high" {
+		t.Fatalf("unexpected rule: %+v", policy[0])
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.conf"), nil)
+	if err == nil {
+		t.Fatal("expected an error for a missing config file")
+	}
+}
@@ -87,6 +87,10 @@ const (
 	ArgFloat
 	ArgInt
 	ArgString
+	// ArgFile is a string argument naming a file path. It is parsed
+	// the same way ArgString is; the distinction only matters to
+	// Complete, which offers matching file paths as completions.
+	ArgFile
 )
 
 // Desc describes available option.
@@ -105,6 +109,18 @@ type Desc struct {
 	ArgName string
 	// Option's description. Used for usage information generation.
 	Description string
+	// Required makes Parse return an error if the option is missing
+	// from the parsed command line arguments.
+	Required bool
+	// Default is materialized into Options when the option is
+	// missing from the parsed command line arguments. It is ignored
+	// for ArgNone options, which are simply absent from Options when
+	// not passed.
+	Default interface{}
+	// Choices, if not empty, restricts the option's argument to one
+	// of the listed strings. Int and float arguments are compared
+	// after being formatted back to a string.
+	Choices []string
 }
 
 type descSlice []*Desc
@@ -345,12 +361,35 @@ func Usage(descs []*Desc) string {
 		b.WriteString(l)
 		b.WriteString(strings.Repeat(" ", max-len(l)+2))
 		b.WriteString(d.Description)
+		b.WriteString(descAnnotation(d))
 		b.WriteString("\n")
 	}
 
 	return b.String()
 }
 
+// descAnnotation returns the trailing "(required)", "(default: ...)"
+// and "[one of: ...]" markers for d, in that order, or an empty
+// string if none of Required, Default or Choices apply.
+func descAnnotation(d *Desc) string {
+	var parts []string
+
+	if d.Required {
+		parts = append(parts, "(required)")
+	}
+	if d.Default != nil {
+		parts = append(parts, fmt.Sprintf("(default: %v)", d.Default))
+	}
+	if len(d.Choices) > 0 {
+		parts = append(parts, "[one of: "+strings.Join(d.Choices, ", ")+"]")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " " + strings.Join(parts, " ")
+}
+
 // Parse parses given command line arguments. Available application arguments
 // are defined by `descs` argument.
 // Returns a list of parsed options and a list of free arguments.
@@ -378,7 +417,53 @@ func Parse(args []string, descs []*Desc) (Options, []string, error) {
 		}
 	}
 
-	return join(opts), params, nil
+	return applyConstraints(join(opts), descs, params)
+}
+
+// applyConstraints enforces Required and materializes Default for
+// every descriptor missing from opts, returning the resulting
+// Options and args unchanged, or an error if a required option is
+// missing or a materialized Default isn't one of Choices.
+func applyConstraints(opts Options, descs []*Desc, args []string) (Options, []string, error) {
+	for _, d := range descs {
+		if hasOption(opts, d) {
+			continue
+		}
+		if d.Arg != ArgNone && d.Default != nil {
+			name := descName(d)
+			if err := checkChoices(d, name, d.Default); err != nil {
+				return nil, nil, err
+			}
+			opts = append(opts, &Option{Desc: d, Args: []interface{}{d.Default}})
+			continue
+		}
+		if d.Required {
+			return nil, nil, fmt.Errorf("option '%s' is required", descName(d))
+		}
+	}
+
+	return opts, args, nil
+}
+
+// hasOption returns true if opts already contains an option for d.
+func hasOption(opts Options, d *Desc) bool {
+	for _, o := range opts {
+		if o.Desc == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// descName returns the name used to refer to d in error messages:
+// its long name if set, its short name otherwise.
+func descName(d *Desc) string {
+	if d.Long != "" {
+		return d.Long
+	}
+
+	return d.Short
 }
 
 // parseDashed parses one command line argument which starts with `-`.
@@ -424,7 +509,7 @@ func parseDashed(descs []*Desc, args []string) ([]*Option, int, error) {
 				} else {
 					a = strings.Join(runes[i+1:], "")
 				}
-				v, err := parseArg(string(r), d.Arg, a)
+				v, err := parseArg(d, a)
 				if err != nil {
 					return nil, 0, err
 				}
@@ -462,7 +547,7 @@ func parseDashed(descs []*Desc, args []string) ([]*Option, int, error) {
 				n++
 			}
 
-			v, err := parseArg(name, d.Arg, val)
+			v, err := parseArg(d, val)
 			if err != nil {
 				return nil, 0, err
 			}
@@ -483,9 +568,13 @@ func parseDashed(descs []*Desc, args []string) ([]*Option, int, error) {
 }
 
 // parseArg converts raw argument string to the type defined
-// by option's descriptor.
-func parseArg(name string, tp ArgType, value string) (interface{}, error) {
-	switch tp {
+// by option's descriptor, and checks the result against d.Choices,
+// if any are declared.
+func parseArg(d *Desc, value string) (interface{}, error) {
+	name := descName(d)
+	var v interface{}
+
+	switch d.Arg {
 	case ArgNone:
 		return nil, nil
 	case ArgFloat:
@@ -493,18 +582,42 @@ func parseArg(name string, tp ArgType, value string) (interface{}, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid argument for option '%s'", name)
 		}
-		return f, nil
+		v = f
 	case ArgInt:
 		i, err := strconv.Atoi(value)
 		if err != nil {
 			return nil, fmt.Errorf("invalid argument for option '%s'", name)
 		}
-		return i, nil
-	case ArgString:
-		return value, nil
+		v = i
+	case ArgString, ArgFile:
+		v = value
 	default:
 		return nil, errors.New("argument not allowed")
 	}
+
+	if err := checkChoices(d, name, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// checkChoices reports an error if v is not one of d.Choices.
+// Descriptors without Choices accept any value.
+func checkChoices(d *Desc, name string, v interface{}) error {
+	if len(d.Choices) == 0 {
+		return nil
+	}
+
+	s := fmt.Sprintf("%v", v)
+	for _, c := range d.Choices {
+		if c == s {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid value '%s' for option '%s', must be one of: %s",
+		s, name, strings.Join(d.Choices, ", "))
 }
 
 // join joins two or more options with the same name.
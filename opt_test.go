@@ -25,7 +25,7 @@ import (
 func TestDoubleDash(t *testing.T) {
 	testArgs := []string{"-a", "A", "--", "-b", "C"}
 	descs := []*Desc{
-		{"a", "", ArgNone, "", ""},
+		{"a", "", ArgNone, "", "", false, nil, nil},
 	}
 	expOpts := []*Option{{descs[0], nil}}
 	expArgs := []string{"A", "-b", "C"}
@@ -41,11 +41,11 @@ func TestDoubleDash(t *testing.T) {
 func TestShort(t *testing.T) {
 	testArgs := []string{"-a", "-b", "-cd", "-e"}
 	descs := []*Desc{
-		{"a", "", ArgNone, "", ""},
-		{"b", "", ArgNone, "", ""},
-		{"c", "", ArgNone, "", ""},
-		{"d", "", ArgNone, "", ""},
-		{"e", "", ArgNone, "", ""},
+		{"a", "", ArgNone, "", "", false, nil, nil},
+		{"b", "", ArgNone, "", "", false, nil, nil},
+		{"c", "", ArgNone, "", "", false, nil, nil},
+		{"d", "", ArgNone, "", "", false, nil, nil},
+		{"e", "", ArgNone, "", "", false, nil, nil},
 	}
 	expected := []*Option{
 		{descs[0], nil},
@@ -66,9 +66,9 @@ func TestShort(t *testing.T) {
 func TestShortArgument(t *testing.T) {
 	testArgs := []string{"-a", "A", "-a", "AA", "-c", "C", "-bc", "CC"}
 	descs := []*Desc{
-		{"a", "", ArgString, "", ""},
-		{"b", "", ArgNone, "", ""},
-		{"c", "", ArgString, "", ""},
+		{"a", "", ArgString, "", "", false, nil, nil},
+		{"b", "", ArgNone, "", "", false, nil, nil},
+		{"c", "", ArgString, "", "", false, nil, nil},
 	}
 	expected := []*Option{
 		{descs[0], []interface{}{"A", "AA"}},
@@ -87,9 +87,9 @@ func TestShortArgument(t *testing.T) {
 func TestLong(t *testing.T) {
 	testArgs := []string{"--a-opt", "--b-opt", "--c-opt"}
 	descs := []*Desc{
-		{"", "a-opt", ArgNone, "", ""},
-		{"", "b-opt", ArgNone, "", ""},
-		{"", "c-opt", ArgNone, "", ""},
+		{"", "a-opt", ArgNone, "", "", false, nil, nil},
+		{"", "b-opt", ArgNone, "", "", false, nil, nil},
+		{"", "c-opt", ArgNone, "", "", false, nil, nil},
 	}
 	expected := []*Option{
 		{descs[0], nil},
@@ -108,8 +108,8 @@ func TestLong(t *testing.T) {
 func TestLongArgument(t *testing.T) {
 	testArgs := []string{"--a-opt", "A", "--b-opt", "B", "--b-opt=BB"}
 	descs := []*Desc{
-		{"", "a-opt", ArgString, "", ""},
-		{"", "b-opt", ArgString, "", ""},
+		{"", "a-opt", ArgString, "", "", false, nil, nil},
+		{"", "b-opt", ArgString, "", "", false, nil, nil},
 	}
 	expected := []*Option{
 		{descs[0], []interface{}{"A"}},
@@ -127,9 +127,9 @@ func TestLongArgument(t *testing.T) {
 func TestBool(t *testing.T) {
 	testArgs := []string{"-ab", "-c"}
 	descs := []*Desc{
-		{"a", "", ArgNone, "", ""},
-		{"b", "", ArgNone, "", ""},
-		{"c", "", ArgNone, "", ""},
+		{"a", "", ArgNone, "", "", false, nil, nil},
+		{"b", "", ArgNone, "", "", false, nil, nil},
+		{"c", "", ArgNone, "", "", false, nil, nil},
 	}
 
 	opts, _, err := Parse(testArgs, descs)
@@ -154,8 +154,8 @@ func TestBool(t *testing.T) {
 func TestString(t *testing.T) {
 	testArgs := []string{"--a-opt", "A", "-bB"}
 	descs := []*Desc{
-		{"a", "a-opt", ArgString, "", ""},
-		{"b", "b-opt", ArgString, "", ""},
+		{"a", "a-opt", ArgString, "", "", false, nil, nil},
+		{"b", "b-opt", ArgString, "", "", false, nil, nil},
 	}
 
 	opts, _, err := Parse(testArgs, descs)
@@ -185,8 +185,8 @@ func TestString(t *testing.T) {
 func TestTypeInt(t *testing.T) {
 	testArgs := []string{"-a", "1", "--aaa", "2", "-b", "3"}
 	descs := []*Desc{
-		{"a", "aaa", ArgInt, "", ""},
-		{"b", "bbb", ArgInt, "", ""},
+		{"a", "aaa", ArgInt, "", "", false, nil, nil},
+		{"b", "bbb", ArgInt, "", "", false, nil, nil},
 	}
 	expected := []*Option{
 		{descs[0], []interface{}{1, 2}},
@@ -222,8 +222,8 @@ func TestTypeInt(t *testing.T) {
 func TestTypeFloat(t *testing.T) {
 	testArgs := []string{"-a", "1.23", "--aaa", "2.34", "-b", "3"}
 	descs := []*Desc{
-		{"a", "aaa", ArgFloat, "", ""},
-		{"b", "bbb", ArgFloat, "", ""},
+		{"a", "aaa", ArgFloat, "", "", false, nil, nil},
+		{"b", "bbb", ArgFloat, "", "", false, nil, nil},
 	}
 	expected := []*Option{
 		{descs[0], []interface{}{1.23, 2.34}},
@@ -265,13 +265,13 @@ func TestUsage(t *testing.T) {
 
 	descs := []*Desc{
 		{"", "delete", ArgNone,
-			"", "delete item"},
+			"", "delete item", false, nil, nil},
 		{"a", "add", ArgNone,
-			"", "add new item"},
+			"", "add new item", false, nil, nil},
 		{"p", "path", ArgString,
-			"path", "path to store output files to"},
+			"path", "path to store output files to", false, nil, nil},
 		{"h", "", ArgNone,
-			"", "display help information and exit"},
+			"", "display help information and exit", false, nil, nil},
 	}
 
 	if Usage(descs) != expected {
@@ -279,6 +279,82 @@ func TestUsage(t *testing.T) {
 	}
 }
 
+func TestUsageAnnotations(t *testing.T) {
+	expected := `  -k, --key <key>    api key (required)
+  -l, --level <lvl>  log level (default: low) [one of: low, medium, high]
+`
+
+	descs := []*Desc{
+		{"k", "key", ArgString, "key", "api key", true, nil, nil},
+		{"l", "level", ArgString, "lvl", "log level", false, "low",
+			[]string{"low", "medium", "high"}},
+	}
+
+	if Usage(descs) != expected {
+		t.Fatalf("%q", Usage(descs))
+	}
+}
+
+func TestRequired(t *testing.T) {
+	descs := []*Desc{
+		{"k", "key", ArgString, "key", "api key", true, nil, nil},
+	}
+
+	if _, _, err := Parse(nil, descs); err == nil {
+		t.Fatal("expected an error for a missing required option")
+	}
+	if _, _, err := Parse([]string{"-k", "secret"}, descs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	descs := []*Desc{
+		{"p", "path", ArgString, "path", "path", false, "/tmp", nil},
+	}
+
+	opts, _, err := Parse(nil, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := opts.String("path"); s != "/tmp" {
+		t.Fatalf("expected default value, got '%s'", s)
+	}
+
+	opts, _, err = Parse([]string{"-p", "/home"}, descs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := opts.String("path"); s != "/home" {
+		t.Fatalf("expected passed value, got '%s'", s)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	descs := []*Desc{
+		{"l", "level", ArgString, "level", "log level", false, nil,
+			[]string{"low", "medium", "high"}},
+	}
+
+	if _, _, err := Parse([]string{"-l", "extreme"}, descs); err == nil {
+		t.Fatal("expected an error for a value outside of choices")
+	}
+	if _, _, err := Parse([]string{"-l", "high"}, descs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChoicesRejectsBadDefault(t *testing.T) {
+	descs := []*Desc{
+		{"l", "level", ArgString, "level", "log level", false, "extreme",
+			[]string{"low", "medium", "high"}},
+	}
+
+	if _, _, err := Parse(nil, descs); err == nil {
+		t.Fatal("expected an error for a default value outside of choices")
+	}
+}
+
 func assertOptions(t *testing.T, expected, actual []*Option) {
 	if len(expected) != len(actual) {
 		t.Fatalf("%d options expected but %d found",
@@ -0,0 +1,196 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PosDesc describes a positional argument, as opposed to a `Desc`
+// dashed option.
+type PosDesc struct {
+	// Name of the positional argument. Used to look values up via
+	// Options.Positional and friends, and for Synopsis rendering.
+	Name string
+	// Arg is the positional's type. ArgNone is not a valid value,
+	// a positional argument always carries a value.
+	Arg ArgType
+	// Min and Max bound how many values this positional accepts.
+	// A fixed positional sets Min == Max == 1. Only the last entry
+	// of a `ParseWithPositional` spec list may differ, accepting
+	// between Min and Max values; Max < 0 means no upper bound.
+	Min int
+	Max int
+	// Description of the positional argument. It is copied into the
+	// Desc of the positional's parsed Option; Synopsis doesn't render it.
+	Description string
+}
+
+// ParseWithPositional parses args the same way Parse does, then
+// validates the resulting free arguments against pos: an ordered list
+// of positional specs where every entry but the last is fixed
+// (Min == Max), and the last one may additionally be variadic,
+// accepting between Min and Max values (e.g. "'rest' requires 1-2
+// arguments, got 3"). Parsed positional values are appended to the
+// returned Options, keyed by their PosDesc.Name and retrievable
+// through Options.Positional, PositionalInts and PositionalFloats.
+func ParseWithPositional(args []string, descs []*Desc, pos []*PosDesc) (Options, error) {
+	opts, params, err := Parse(args, descs)
+	if err != nil {
+		return nil, err
+	}
+
+	posOpts, err := bindPositional(params, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(opts, posOpts...), nil
+}
+
+// bindPositional validates params against pos and returns one
+// synthesized Option per pos entry, holding its matched values.
+func bindPositional(params []string, pos []*PosDesc) ([]*Option, error) {
+	for i, p := range pos {
+		if p.Arg == ArgNone {
+			return nil, fmt.Errorf("positional '%s' must declare an argument type", p.Name)
+		}
+		if i < len(pos)-1 && p.Min != p.Max {
+			return nil, fmt.Errorf("positional '%s' is not the last one and can't be variadic", p.Name)
+		}
+	}
+
+	var last *PosDesc
+	fixed := 0
+	if len(pos) > 0 {
+		last = pos[len(pos)-1]
+		for _, p := range pos[:len(pos)-1] {
+			fixed += p.Min
+		}
+	}
+
+	min := fixed
+	max := fixed
+	unbounded := false
+	if last != nil {
+		min += last.Min
+		if last.Max < 0 {
+			unbounded = true
+		} else {
+			max += last.Max
+		}
+	}
+
+	if len(params) < min {
+		return nil, fmt.Errorf("not enough arguments, %d required, got %d",
+			min, len(params))
+	}
+	if !unbounded && len(params) > max {
+		if last != nil && last.Min != last.Max {
+			return nil, fmt.Errorf("'%s' requires %d-%d arguments, got %d",
+				last.Name, last.Min, last.Max, len(params)-fixed)
+		}
+		return nil, fmt.Errorf("too many arguments, at most %d expected, got %d",
+			max, len(params))
+	}
+
+	var opts []*Option
+	idx := 0
+
+	for i, p := range pos {
+		n := p.Min
+		if i == len(pos)-1 {
+			n = len(params) - idx
+		}
+
+		d := &Desc{Long: p.Name, Arg: p.Arg, ArgName: p.Name, Description: p.Description}
+		vals := make([]interface{}, 0, n)
+
+		for j := 0; j < n; j++ {
+			v, err := parseArg(d, params[idx])
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+			idx++
+		}
+
+		opts = append(opts, &Option{Desc: d, Args: vals})
+	}
+
+	return opts, nil
+}
+
+// Positional returns the string positional argument's value by its
+// PosDesc.Name. Second return value is false if it wasn't passed.
+func (o Options) Positional(name string) (string, bool) {
+	return o.String(name)
+}
+
+// PositionalInt is the Positional equivalent for int positionals.
+func (o Options) PositionalInt(name string) (int, bool) {
+	return o.Int(name)
+}
+
+// PositionalFloat is the Positional equivalent for float64 positionals.
+func (o Options) PositionalFloat(name string) (float64, bool) {
+	return o.Float(name)
+}
+
+// PositionalStrings returns every value bound to a variadic string
+// positional by its PosDesc.Name.
+func (o Options) PositionalStrings(name string) []string {
+	return o.Strings(name)
+}
+
+// PositionalInts is the PositionalStrings equivalent for int positionals.
+func (o Options) PositionalInts(name string) []int {
+	return o.Ints(name)
+}
+
+// PositionalFloats is the PositionalStrings equivalent for float64
+// positionals.
+func (o Options) PositionalFloats(name string) []float64 {
+	return o.Floats(name)
+}
+
+// Synopsis renders a single usage synopsis line for prog out of pos.
+// A fixed positional is rendered as its upper-cased Name, a variadic
+// one as "NAME...", and an optional one (Min == 0) is wrapped in
+// brackets.
+//
+// Return string example:
+//   myapp [OPTIONS] SRC DST [FILE...]
+func Synopsis(prog string, pos []*PosDesc) string {
+	parts := []string{prog, "[OPTIONS]"}
+
+	for _, p := range pos {
+		n := strings.ToUpper(p.Name)
+		if p.Max < 0 || p.Max > 1 {
+			n += "..."
+		}
+		if p.Min == 0 {
+			n = "[" + n + "]"
+		}
+
+		parts = append(parts, n)
+	}
+
+	return strings.Join(parts, " ") + "\n"
+}
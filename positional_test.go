@@ -0,0 +1,87 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWithPositionalFixed(t *testing.T) {
+	descs := []*Desc{
+		{"v", "verbose", ArgNone, "", "be verbose", false, nil, nil},
+	}
+	pos := []*PosDesc{
+		{"src", ArgString, 1, 1, "source path"},
+		{"dst", ArgString, 1, 1, "destination path"},
+	}
+
+	opts, err := ParseWithPositional([]string{"-v", "a", "b"}, descs, pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Bool("verbose") {
+		t.Fatal("expected 'verbose' to be set")
+	}
+	if s, ok := opts.Positional("src"); !ok || s != "a" {
+		t.Fatalf("unexpected src: %v, %v", s, ok)
+	}
+	if s, ok := opts.Positional("dst"); !ok || s != "b" {
+		t.Fatalf("unexpected dst: %v, %v", s, ok)
+	}
+}
+
+func TestParseWithPositionalVariadic(t *testing.T) {
+	pos := []*PosDesc{
+		{"src", ArgString, 1, 1, "source path"},
+		{"rest", ArgInt, 1, 2, "rest"},
+	}
+
+	opts, err := ParseWithPositional([]string{"a", "1", "2"}, nil, pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := opts.Positional("src"); s != "a" {
+		t.Fatalf("unexpected src: %s", s)
+	}
+	if is := opts.PositionalInts("rest"); !reflect.DeepEqual(is, []int{1, 2}) {
+		t.Fatalf("unexpected rest: %v", is)
+	}
+
+	_, err = ParseWithPositional([]string{"a", "1", "2", "3"}, nil, pos)
+	if err == nil {
+		t.Fatal("expected an error, too many arguments for 'rest'")
+	}
+	_, err = ParseWithPositional([]string{"a"}, nil, pos)
+	if err == nil {
+		t.Fatal("expected an error, not enough arguments for 'rest'")
+	}
+}
+
+func TestSynopsis(t *testing.T) {
+	pos := []*PosDesc{
+		{"src", ArgString, 1, 1, ""},
+		{"dst", ArgString, 1, 1, ""},
+		{"file", ArgString, 0, -1, ""},
+	}
+
+	expected := "myapp [OPTIONS] SRC DST [FILE...]\n"
+	if s := Synopsis("myapp", pos); s != expected {
+		t.Fatalf("%q", s)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CompletionFlag is the hidden option CompletionScript's generated
+// scripts invoke the program with to ask for candidate completions.
+const CompletionFlag = "--__complete"
+
+// Complete returns candidate completions for the word being typed at
+// args[cword]: the Choices of the option preceding it, if any, file
+// paths matching it for an ArgFile option, or, if it isn't an
+// option's argument, every long option name prefixed with "--" and,
+// if descs has any, a single "-" candidate grouping every short name
+// together, the way this package's own parser accepts them. Results
+// are filtered down to the ones starting with the word being
+// completed.
+func Complete(descs []*Desc, args []string, cword int) []string {
+	var cur, prev string
+	if cword >= 0 && cword < len(args) {
+		cur = args[cword]
+	}
+	if cword-1 >= 0 && cword-1 < len(args) {
+		prev = args[cword-1]
+	}
+
+	if strings.HasPrefix(prev, "-") {
+		if d := findDesc(descs, strings.TrimLeft(prev, "-")); d != nil && d.Arg != ArgNone {
+			return completeValue(d, cur)
+		}
+	}
+
+	return completeOption(descs, cur)
+}
+
+// completeValue completes an option's argument: its Choices, if any
+// are declared, or matching file paths for an ArgFile option.
+func completeValue(d *Desc, cur string) []string {
+	if len(d.Choices) > 0 {
+		return filterPrefix(d.Choices, cur)
+	}
+	if d.Arg == ArgFile {
+		matches, err := filepath.Glob(cur + "*")
+		if err != nil {
+			return nil
+		}
+		sort.Strings(matches)
+
+		return matches
+	}
+
+	return nil
+}
+
+// completeOption completes an option name: every long name prefixed
+// with "--", and a single "-" candidate grouping every short name.
+func completeOption(descs []*Desc, cur string) []string {
+	var candidates []string
+	var shorts string
+
+	for _, d := range descs {
+		if d.Long != "" {
+			candidates = append(candidates, "--"+d.Long)
+		}
+		if d.Short != "" {
+			shorts += d.Short
+		}
+	}
+	if shorts != "" {
+		candidates = append(candidates, "-"+shorts)
+	}
+	sort.Strings(candidates)
+
+	return filterPrefix(candidates, cur)
+}
+
+// filterPrefix returns the candidates starting with cur, or all of
+// them if cur is empty.
+func filterPrefix(candidates []string, cur string) []string {
+	if cur == "" {
+		return candidates
+	}
+
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, cur) {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// HandleCompletion checks os.Args for CompletionFlag and, if present,
+// prints the candidates Complete computes, one per line, then
+// terminates the program with os.Exit(0). It is a no-op otherwise.
+// Call it once near the top of main, before Parse, to give a
+// CompletionScript-generated script dynamic tab completion.
+func HandleCompletion(descs []*Desc) {
+	args := os.Args[1:]
+	if len(args) < 2 || args[0] != CompletionFlag {
+		return
+	}
+
+	cword, err := strconv.Atoi(args[1])
+	if err != nil {
+		return
+	}
+
+	for _, c := range Complete(descs, args[2:], cword) {
+		fmt.Println(c)
+	}
+	os.Exit(0)
+}
+
+// CompletionScript returns a ready-to-source completion script for
+// shell ("bash", "zsh" or "fish") which gives progName dynamic tab
+// completion by invoking it with CompletionFlag.
+func CompletionScript(shell, progName string) string {
+	switch shell {
+	case "bash":
+		// COMP_CWORD indexes COMP_WORDS, which still has the program
+		// name at index 0; args is passed with that name stripped,
+		// so the index handed to Complete must be shifted down by 1.
+		return fmt.Sprintf(`_%[1]s_complete() {
+    COMPREPLY=($(%[1]s %[2]s "$((COMP_CWORD-1))" "${COMP_WORDS[@]:1}"))
+}
+complete -F _%[1]s_complete %[1]s
+`, progName, CompletionFlag)
+	case "zsh":
+		// CURRENT is the 1-based index of the current word into
+		// words, which, like COMP_WORDS, still has the program name
+		// in it; converting to the 0-based index of args, which has
+		// that name stripped, is CURRENT-2.
+		return fmt.Sprintf(`#compdef %[1]s
+_%[1]s() {
+    local -a completions
+    completions=(${(f)"$(%[1]s %[2]s "$((CURRENT-2))" "${words[@]:1}")"})
+    _describe '%[1]s' completions
+}
+compdef _%[1]s %[1]s
+`, progName, CompletionFlag)
+	case "fish":
+		// $words (commandline -opc plus the in-progress token) still
+		// has the program name in it; args is passed with that name
+		// stripped, so the 0-based index handed to Complete is
+		// count($words)-2.
+		return fmt.Sprintf(`function __%[1]s_complete
+    set -l words (commandline -opc) (commandline -ct)
+    %[1]s %[2]s (math (count $words) - 2) $words[2..-1]
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`, progName, CompletionFlag)
+	default:
+		return fmt.Sprintf("# unsupported shell '%s'\n", shell)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompleteOptionNames(t *testing.T) {
+	descs := []*Desc{
+		{"a", "add", ArgNone, "", "add new item", false, nil, nil},
+		{"d", "delete", ArgNone, "", "delete item", false, nil, nil},
+		{"p", "path", ArgString, "path", "path", false, nil, nil},
+	}
+
+	got := Complete(descs, []string{"--a"}, 0)
+	if !reflect.DeepEqual(got, []string{"--add"}) {
+		t.Fatalf("unexpected completions: %v", got)
+	}
+
+	got = Complete(descs, []string{"-"}, 0)
+	want := []string{"--add", "--delete", "--path", "-adp"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected completions: %v", got)
+	}
+}
+
+func TestCompleteChoices(t *testing.T) {
+	descs := []*Desc{
+		{"l", "level", ArgString, "level", "level", false, nil,
+			[]string{"low", "medium", "high"}},
+	}
+
+	got := Complete(descs, []string{"--level", "m"}, 1)
+	if !reflect.DeepEqual(got, []string{"medium"}) {
+		t.Fatalf("unexpected completions: %v", got)
+	}
+}
+
+// TestCompletionScriptIndexMath drives Complete with the exact index
+// CompletionScript's generated scripts compute and hand over, for a
+// simulated bash invocation of `prog --level m` with the cursor on
+// "m": COMP_WORDS=(prog --level m), COMP_CWORD=2,
+// args=COMP_WORDS[1:], cword=COMP_CWORD-1 (see the bash block in
+// CompletionScript). A wrong index here would complete option names
+// instead of the "level" choices.
+func TestCompletionScriptIndexMath(t *testing.T) {
+	descs := []*Desc{
+		{"l", "level", ArgString, "level", "level", false, nil,
+			[]string{"low", "medium", "high"}},
+	}
+
+	compWords := []string{"prog", "--level", "m"}
+	compCword := 2
+
+	args := compWords[1:]
+	cword := compCword - 1
+
+	got := Complete(descs, args, cword)
+	if !reflect.DeepEqual(got, []string{"medium"}) {
+		t.Fatalf("unexpected completions: %v", got)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		s := CompletionScript(shell, "myapp")
+		if s == "" {
+			t.Fatalf("expected a non-empty script for %s", shell)
+		}
+	}
+
+	if s := CompletionScript("tcsh", "myapp"); s == "" {
+		t.Fatal("expected a fallback message for an unsupported shell")
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// field binds a single struct field to its synthesized Desc.
+type field struct {
+	desc  *Desc
+	value reflect.Value
+}
+
+// Register reflects over v, a pointer to a struct, and synthesizes a
+// `Desc` list from its fields' `opt` struct tags. It is useful on its
+// own when only the descriptors are needed, for example to print
+// `Usage`; most callers will use `ParseInto` instead.
+//
+// Recognized tags:
+//	opt      short and long option name, e.g. `opt:"p,path"`
+//	desc     option description, used by Usage
+//	arg      argument name, used by Usage; defaults to the lower-cased
+//	         field name
+//	default  default value, materialized when the option is not passed;
+//	         ignored for bool fields
+//	env      environment variable consulted, at Register/ParseInto
+//	         time, as the option's default; takes priority over the
+//	         `default` tag; ignored for bool fields
+//	required marks the option mandatory, `required:"true"`
+//	choices  comma separated list of allowed values
+//
+// Supported field types are string, int, float64, bool and slices of
+// string, int and float64 for options repeated on the command line.
+func Register(v interface{}) ([]*Desc, error) {
+	_, fields, err := register(v)
+	if err != nil {
+		return nil, err
+	}
+
+	descs := make([]*Desc, len(fields))
+	for i, f := range fields {
+		descs[i] = f.desc
+	}
+
+	return descs, nil
+}
+
+// ParseInto parses args according to the `opt` struct tags found on
+// v's fields (see `Register`) and stores parsed values directly into
+// v, which must be a pointer to a struct. `Required`, `Default` and
+// `Choices`, synthesized from the corresponding tags onto each field's
+// `Desc`, are enforced by `Parse` itself.
+//
+// Example:
+//	type Config struct {
+//		Path string `opt:"p,path" desc:"path to store output files to" default:"/tmp" env:"MYAPP_PATH"`
+//		Add  bool   `opt:"a,add" desc:"add new item"`
+//	}
+//	var cfg Config
+//	args, err := opt.ParseInto(os.Args[1:], &cfg)
+func ParseInto(args []string, v interface{}) ([]string, error) {
+	descs, fields, err := register(v)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, params, err := Parse(args, descs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		if err := f.bind(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return params, nil
+}
+
+// register reflects over v and builds the internal field list
+// `Register` and `ParseInto` are implemented on top of.
+func register(v interface{}) ([]*Desc, []*field, error) {
+	p := reflect.ValueOf(v)
+	if p.Kind() != reflect.Ptr || p.Elem().Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("opt: ParseInto/Register expect a pointer to a struct")
+	}
+
+	s := p.Elem()
+	t := s.Type()
+	var descs []*Desc
+	var fields []*field
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("opt")
+		if !ok {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, nil, fmt.Errorf("field '%s': unexported fields can't be bound", sf.Name)
+		}
+
+		short, long, err := splitOptTag(tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field '%s': %s", sf.Name, err)
+		}
+
+		arg, err := argType(sf.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field '%s': %s", sf.Name, err)
+		}
+
+		argName := sf.Tag.Get("arg")
+		if argName == "" {
+			argName = strings.ToLower(sf.Name)
+		}
+
+		var choices []string
+		if c := sf.Tag.Get("choices"); c != "" {
+			choices = strings.Split(c, ",")
+		}
+
+		d := &Desc{
+			Short:       short,
+			Long:        long,
+			Arg:         arg,
+			ArgName:     argName,
+			Description: sf.Tag.Get("desc"),
+			Required:    sf.Tag.Get("required") == "true",
+			Choices:     choices,
+		}
+
+		raw, hasRaw := defaultOf(sf)
+		if hasRaw && arg != ArgNone {
+			v, err := parseArg(d, raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("field '%s': %s", sf.Name, err)
+			}
+			d.Default = v
+		}
+
+		descs = append(descs, d)
+		fields = append(fields, &field{desc: d, value: s.Field(i)})
+	}
+
+	return descs, fields, nil
+}
+
+// defaultOf resolves a field's default value: its `env` variable, if
+// set in the environment, takes priority over its `default` tag.
+func defaultOf(sf reflect.StructField) (string, bool) {
+	if env := sf.Tag.Get("env"); env != "" {
+		if v, ok := os.LookupEnv(env); ok {
+			return v, true
+		}
+	}
+
+	return sf.Tag.Lookup("default")
+}
+
+// splitOptTag parses the `opt:"short,long"` tag value. Either part can
+// be omitted, e.g. `opt:"p,"` or `opt:",path"`.
+func splitOptTag(tag string) (short, long string, err error) {
+	parts := strings.SplitN(tag, ",", 2)
+	short = parts[0]
+	if len(parts) > 1 {
+		long = parts[1]
+	}
+	if short == "" && long == "" {
+		return "", "", fmt.Errorf("invalid opt tag '%s'", tag)
+	}
+
+	return short, long, nil
+}
+
+// argType maps a struct field's Go type to the matching ArgType.
+func argType(t reflect.Type) (ArgType, error) {
+	k := t.Kind()
+	if k == reflect.Slice {
+		if t.Elem().Kind() == reflect.Bool {
+			return ArgNone, fmt.Errorf("unsupported option type '%s': "+
+				"ArgNone options are booleans, not repeatable", t)
+		}
+		k = t.Elem().Kind()
+	}
+
+	switch k {
+	case reflect.Bool:
+		return ArgNone, nil
+	case reflect.Int:
+		return ArgInt, nil
+	case reflect.Float64:
+		return ArgFloat, nil
+	case reflect.String:
+		return ArgString, nil
+	default:
+		return ArgNone, fmt.Errorf("unsupported option type '%s'", t)
+	}
+}
+
+// bind resolves f's value out of opts, already fully constrained by
+// Parse, and stores it into the bound struct field.
+func (f *field) bind(opts Options) error {
+	name := descName(f.desc)
+
+	if f.desc.Arg == ArgNone {
+		f.value.SetBool(opts.Bool(name))
+		return nil
+	}
+
+	if f.value.Kind() == reflect.Slice {
+		vs := opts.args(name)
+		s := reflect.MakeSlice(f.value.Type(), len(vs), len(vs))
+		for i, v := range vs {
+			s.Index(i).Set(reflect.ValueOf(v))
+		}
+		f.value.Set(s)
+
+		return nil
+	}
+
+	if v := opts.arg(name); v != nil {
+		f.value.Set(reflect.ValueOf(v))
+	}
+
+	return nil
+}
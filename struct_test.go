@@ -0,0 +1,149 @@
+// Copyright 2016 Viacheslav Chimishuk <vchimishuk@yandex.ru>
+//
+// This file is part of opt library.
+//
+// opt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// opt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with opt. If not, see <http://www.gnu.org/licenses/>.
+
+package opt
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseIntoBasic(t *testing.T) {
+	type config struct {
+		Path string `opt:"p,path" desc:"path" default:"/tmp"`
+		Add  bool   `opt:"a,add" desc:"add new item"`
+		Num  int    `opt:"n,num" desc:"a number" default:"0"`
+	}
+
+	var cfg config
+	args, err := ParseInto([]string{"-a", "-p", "/home", "rest"}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Add || cfg.Path != "/home" || cfg.Num != 0 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if !reflect.DeepEqual(args, []string{"rest"}) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestParseIntoDefault(t *testing.T) {
+	type config struct {
+		Path string `opt:"p,path" default:"/tmp"`
+	}
+
+	var cfg config
+	_, err := ParseInto(nil, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Path != "/tmp" {
+		t.Fatalf("expected default value, got '%s'", cfg.Path)
+	}
+}
+
+func TestParseIntoEnv(t *testing.T) {
+	type config struct {
+		Path string `opt:"p,path" default:"/tmp" env:"OPT_TEST_PATH"`
+	}
+
+	os.Setenv("OPT_TEST_PATH", "/env")
+	defer os.Unsetenv("OPT_TEST_PATH")
+
+	var cfg config
+	_, err := ParseInto(nil, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Path != "/env" {
+		t.Fatalf("expected env value, got '%s'", cfg.Path)
+	}
+}
+
+func TestParseIntoRequired(t *testing.T) {
+	type config struct {
+		Path string `opt:"p,path" required:"true"`
+	}
+
+	var cfg config
+	_, err := ParseInto(nil, &cfg)
+	if err == nil {
+		t.Fatal("expected an error for missing required option")
+	}
+}
+
+func TestParseIntoChoices(t *testing.T) {
+	type config struct {
+		Level string `opt:"l,level" choices:"low,medium,high"`
+	}
+
+	var cfg config
+	_, err := ParseInto([]string{"-l", "extreme"}, &cfg)
+	if err == nil {
+		t.Fatal("expected an error for a value outside of choices")
+	}
+
+	cfg = config{}
+	_, err = ParseInto([]string{"-l", "high"}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "high" {
+		t.Fatalf("unexpected level: %s", cfg.Level)
+	}
+}
+
+func TestParseIntoBoolSliceRejected(t *testing.T) {
+	type config struct {
+		V []bool `opt:"v,verbose"`
+	}
+
+	var cfg config
+	_, err := ParseInto([]string{"-v"}, &cfg)
+	if err == nil {
+		t.Fatal("expected an error for a []bool field")
+	}
+}
+
+func TestParseIntoUnexportedRejected(t *testing.T) {
+	type config struct {
+		path string `opt:"p,path"`
+	}
+
+	var cfg config
+	_, err := ParseInto([]string{"-p", "/tmp"}, &cfg)
+	if err == nil {
+		t.Fatal("expected an error for an unexported field")
+	}
+}
+
+func TestParseIntoSlice(t *testing.T) {
+	type config struct {
+		Tags []string `opt:"t,tag"`
+	}
+
+	var cfg config
+	_, err := ParseInto([]string{"-t", "a", "--tag", "b"}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Fatalf("unexpected tags: %v", cfg.Tags)
+	}
+}